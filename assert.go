@@ -0,0 +1,10 @@
+package main
+
+// assert panics with msg if cond is false. Used for invariants that
+// indicate a bug in the caller (e.g. out-of-range coordinates) rather than
+// conditions callers are expected to handle.
+func assert(cond bool, msg string) {
+	if !cond {
+		panic("slimy: assertion failed: " + msg)
+	}
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+// Backend computes slime-chunk data for a section and searches it against a
+// mask in one call. searchContext uses whichever Backend SelectBackend
+// picks, so the worker loop doesn't care whether a section is evaluated on
+// the CPU or offloaded to a GPU compute shader. Search takes world and mask
+// together, rather than splitting precompute from search, so a Backend
+// shared across worker goroutines never has to stash per-call state between
+// two separate calls.
+type Backend interface {
+	Search(world World, sec *Section, mask Mask, threshold int) []SearchResult
+}
+
+// cpuBackend is the pure-Go reference implementation, backed directly by
+// Section.Compute and Section.Search.
+type cpuBackend struct{}
+
+func (cpuBackend) Search(world World, sec *Section, mask Mask, threshold int) []SearchResult {
+	sec.Compute(world)
+	return sec.Search(mask, threshold)
+}
+
+// SelectBackend picks the Backend to use. SLIMY_BACKEND can force a choice
+// ("cpu" or "gpu"); otherwise the GPU backend is used when one is compiled
+// in (build tag "gpu") and a device is present, falling back to the CPU
+// backend.
+func SelectBackend() (Backend, error) {
+	switch want := os.Getenv("SLIMY_BACKEND"); want {
+	case "cpu":
+		return cpuBackend{}, nil
+	case "gpu":
+		b, err := newGPUBackend()
+		if err != nil {
+			return nil, fmt.Errorf("SLIMY_BACKEND=gpu: %w", err)
+		}
+		return b, nil
+	case "":
+		if b, err := newGPUBackend(); err == nil {
+			return b, nil
+		}
+		return cpuBackend{}, nil
+	default:
+		return nil, fmt.Errorf("unknown SLIMY_BACKEND %q (want \"cpu\" or \"gpu\")", want)
+	}
+}
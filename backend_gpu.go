@@ -0,0 +1,570 @@
+//go:build gpu
+
+package main
+
+import (
+	_ "embed"
+	"fmt"
+	"sync"
+	"unsafe"
+
+	vk "github.com/vulkan-go/vulkan"
+)
+
+// shaders/slime.spv is not produced by go build, yet go:embed needs it
+// present before this file even compiles. Run `make shaders` (or
+// `go generate -tags gpu ./...`) to build it from shaders/slime.comp before
+// building with -tags gpu, and again after editing the shader.
+//
+//go:generate make shaders
+//go:embed shaders/slime.spv
+var slimeShaderSPIRV []byte
+
+// localSize must match local_size_x/local_size_y in shaders/slime.comp.
+const localSize = 16
+
+// maxGPUResults bounds the on-device result buffer: a section can never
+// match more windows than it has chunks.
+const maxGPUResults = SectionSize * SectionSize
+
+// gpuBackend dispatches a whole Section.Search as a single compute shader
+// invocation: the shader evaluates the slime PRNG for every (x, z) in the
+// section and convolves it against the mask entirely on-device, writing
+// only matches above threshold to an atomic-append result buffer (see
+// shaders/slime.comp). There's no separate precompute step; Search does the
+// whole dispatch.
+//
+// The Vulkan objects here (descriptor pool, command pool) aren't safe to
+// record and submit from multiple goroutines at once, and searchContext
+// runs one Backend across GOMAXPROCS worker goroutines, so Search takes mu
+// for the duration of a dispatch. That serializes GPU work across workers;
+// it's still a win over the CPU backend because each dispatch replaces an
+// entire section's worth of per-chunk PRNG evaluation and mask convolution
+// with one shader invocation.
+type gpuBackend struct {
+	mu sync.Mutex
+
+	instance    vk.Instance
+	physDevice  vk.PhysicalDevice
+	device      vk.Device
+	queue       vk.Queue
+	queueFamily uint32
+
+	shader         vk.ShaderModule
+	descSetLayout  vk.DescriptorSetLayout
+	pipelineLayout vk.PipelineLayout
+	pipeline       vk.Pipeline
+	descPool       vk.DescriptorPool
+	cmdPool        vk.CommandPool
+}
+
+// newGPUBackend opens the first available Vulkan device with compute
+// support and builds the slime-search pipeline, returning an error if no
+// such device exists or any step of setup fails.
+func newGPUBackend() (Backend, error) {
+	if err := vk.Init(); err != nil {
+		return nil, fmt.Errorf("vulkan: %w", err)
+	}
+
+	instance, err := createInstance()
+	if err != nil {
+		return nil, fmt.Errorf("vulkan instance: %w", err)
+	}
+	phys, queueFamily, err := pickComputeDevice(instance)
+	if err != nil {
+		vk.DestroyInstance(instance, nil)
+		return nil, fmt.Errorf("vulkan device selection: %w", err)
+	}
+	device, queue, err := createLogicalDevice(phys, queueFamily)
+	if err != nil {
+		vk.DestroyInstance(instance, nil)
+		return nil, fmt.Errorf("vulkan device: %w", err)
+	}
+
+	b := &gpuBackend{
+		instance: instance, physDevice: phys,
+		device: device, queue: queue, queueFamily: queueFamily,
+	}
+	if err := b.createPipeline(); err != nil {
+		b.Close()
+		return nil, fmt.Errorf("slime pipeline: %w", err)
+	}
+	if err := b.createPools(); err != nil {
+		b.Close()
+		return nil, fmt.Errorf("descriptor/command pools: %w", err)
+	}
+	return b, nil
+}
+
+// Close tears down every Vulkan object newGPUBackend created. It's safe to
+// call on a partially-constructed backend (e.g. from an error path).
+func (b *gpuBackend) Close() {
+	if b.device != nil {
+		vk.DeviceWaitIdle(b.device)
+	}
+	if b.cmdPool != nil {
+		vk.DestroyCommandPool(b.device, b.cmdPool, nil)
+	}
+	if b.descPool != nil {
+		vk.DestroyDescriptorPool(b.device, b.descPool, nil)
+	}
+	if b.pipeline != nil {
+		vk.DestroyPipeline(b.device, b.pipeline, nil)
+	}
+	if b.pipelineLayout != nil {
+		vk.DestroyPipelineLayout(b.device, b.pipelineLayout, nil)
+	}
+	if b.descSetLayout != nil {
+		vk.DestroyDescriptorSetLayout(b.device, b.descSetLayout, nil)
+	}
+	if b.shader != nil {
+		vk.DestroyShaderModule(b.device, b.shader, nil)
+	}
+	if b.device != nil {
+		vk.DestroyDevice(b.device, nil)
+	}
+	if b.instance != nil {
+		vk.DestroyInstance(b.instance, nil)
+	}
+}
+
+func createInstance() (vk.Instance, error) {
+	appInfo := vk.ApplicationInfo{
+		SType:      vk.StructureTypeApplicationInfo,
+		ApiVersion: vk.ApiVersion11,
+	}
+	createInfo := vk.InstanceCreateInfo{
+		SType:            vk.StructureTypeInstanceCreateInfo,
+		PApplicationInfo: &appInfo,
+	}
+	var instance vk.Instance
+	if ret := vk.CreateInstance(&createInfo, nil, &instance); ret != vk.Success {
+		return nil, fmt.Errorf("vkCreateInstance: %d", ret)
+	}
+	return instance, nil
+}
+
+// pickComputeDevice picks the first physical device exposing a queue family
+// with compute support; slimy has no rendering needs, so that's the only
+// requirement.
+func pickComputeDevice(instance vk.Instance) (vk.PhysicalDevice, uint32, error) {
+	var count uint32
+	vk.EnumeratePhysicalDevices(instance, &count, nil)
+	if count == 0 {
+		return nil, 0, fmt.Errorf("no vulkan-capable devices found")
+	}
+	devices := make([]vk.PhysicalDevice, count)
+	vk.EnumeratePhysicalDevices(instance, &count, devices)
+
+	for _, dev := range devices {
+		var qCount uint32
+		vk.GetPhysicalDeviceQueueFamilyProperties(dev, &qCount, nil)
+		props := make([]vk.QueueFamilyProperties, qCount)
+		vk.GetPhysicalDeviceQueueFamilyProperties(dev, &qCount, props)
+		for i := range props {
+			props[i].Deref()
+			if vk.QueueFlagBits(props[i].QueueFlags)&vk.QueueComputeBit != 0 {
+				return dev, uint32(i), nil
+			}
+		}
+	}
+	return nil, 0, fmt.Errorf("no device exposes a compute queue family")
+}
+
+func createLogicalDevice(phys vk.PhysicalDevice, queueFamily uint32) (vk.Device, vk.Queue, error) {
+	queueInfo := vk.DeviceQueueCreateInfo{
+		SType:            vk.StructureTypeDeviceQueueCreateInfo,
+		QueueFamilyIndex: queueFamily,
+		QueueCount:       1,
+		PQueuePriorities: []float32{1},
+	}
+	createInfo := vk.DeviceCreateInfo{
+		SType:                vk.StructureTypeDeviceCreateInfo,
+		QueueCreateInfoCount: 1,
+		PQueueCreateInfos:    []vk.DeviceQueueCreateInfo{queueInfo},
+	}
+	var device vk.Device
+	if ret := vk.CreateDevice(phys, &createInfo, nil, &device); ret != vk.Success {
+		return nil, nil, fmt.Errorf("vkCreateDevice: %d", ret)
+	}
+	var queue vk.Queue
+	vk.GetDeviceQueue(device, queueFamily, 0, &queue)
+	return device, queue, nil
+}
+
+// createPipeline builds the descriptor set layout, pipeline layout, and
+// compute pipeline backing shaders/slime.comp: three storage buffer
+// bindings (mask bits, result count, results) and a push constant block of
+// slimeParams.
+func (b *gpuBackend) createPipeline() error {
+	code := bytesToUint32(slimeShaderSPIRV)
+	shaderInfo := vk.ShaderModuleCreateInfo{
+		SType:    vk.StructureTypeShaderModuleCreateInfo,
+		CodeSize: uint(len(code) * 4),
+		PCode:    code,
+	}
+	var shader vk.ShaderModule
+	if ret := vk.CreateShaderModule(b.device, &shaderInfo, nil, &shader); ret != vk.Success {
+		return fmt.Errorf("vkCreateShaderModule: %d", ret)
+	}
+	b.shader = shader
+
+	bindings := []vk.DescriptorSetLayoutBinding{
+		{Binding: 0, DescriptorType: vk.DescriptorTypeStorageBuffer, DescriptorCount: 1, StageFlags: vk.ShaderStageFlags(vk.ShaderStageComputeBit)},
+		{Binding: 1, DescriptorType: vk.DescriptorTypeStorageBuffer, DescriptorCount: 1, StageFlags: vk.ShaderStageFlags(vk.ShaderStageComputeBit)},
+		{Binding: 2, DescriptorType: vk.DescriptorTypeStorageBuffer, DescriptorCount: 1, StageFlags: vk.ShaderStageFlags(vk.ShaderStageComputeBit)},
+	}
+	setLayoutInfo := vk.DescriptorSetLayoutCreateInfo{
+		SType:        vk.StructureTypeDescriptorSetLayoutCreateInfo,
+		BindingCount: uint32(len(bindings)),
+		PBindings:    bindings,
+	}
+	var setLayout vk.DescriptorSetLayout
+	if ret := vk.CreateDescriptorSetLayout(b.device, &setLayoutInfo, nil, &setLayout); ret != vk.Success {
+		return fmt.Errorf("vkCreateDescriptorSetLayout: %d", ret)
+	}
+	b.descSetLayout = setLayout
+
+	pushRange := vk.PushConstantRange{
+		StageFlags: vk.ShaderStageFlags(vk.ShaderStageComputeBit),
+		Size:       uint32(unsafe.Sizeof(slimeParams{})),
+	}
+	layoutInfo := vk.PipelineLayoutCreateInfo{
+		SType:                  vk.StructureTypePipelineLayoutCreateInfo,
+		SetLayoutCount:         1,
+		PSetLayouts:            []vk.DescriptorSetLayout{setLayout},
+		PushConstantRangeCount: 1,
+		PPushConstantRanges:    []vk.PushConstantRange{pushRange},
+	}
+	var layout vk.PipelineLayout
+	if ret := vk.CreatePipelineLayout(b.device, &layoutInfo, nil, &layout); ret != vk.Success {
+		return fmt.Errorf("vkCreatePipelineLayout: %d", ret)
+	}
+	b.pipelineLayout = layout
+
+	pipelineInfo := vk.ComputePipelineCreateInfo{
+		SType: vk.StructureTypeComputePipelineCreateInfo,
+		Stage: vk.PipelineShaderStageCreateInfo{
+			SType:  vk.StructureTypePipelineShaderStageCreateInfo,
+			Stage:  vk.ShaderStageComputeBit,
+			Module: shader,
+			PName:  "main\x00",
+		},
+		Layout: layout,
+	}
+	pipelines := make([]vk.Pipeline, 1)
+	if ret := vk.CreateComputePipelines(b.device, nil, 1, []vk.ComputePipelineCreateInfo{pipelineInfo}, nil, pipelines); ret != vk.Success {
+		return fmt.Errorf("vkCreateComputePipelines: %d", ret)
+	}
+	b.pipeline = pipelines[0]
+	return nil
+}
+
+func (b *gpuBackend) createPools() error {
+	poolInfo := vk.DescriptorPoolCreateInfo{
+		SType:         vk.StructureTypeDescriptorPoolCreateInfo,
+		MaxSets:       1,
+		PoolSizeCount: 1,
+		PPoolSizes:    []vk.DescriptorPoolSize{{Type: vk.DescriptorTypeStorageBuffer, DescriptorCount: 3}},
+	}
+	var pool vk.DescriptorPool
+	if ret := vk.CreateDescriptorPool(b.device, &poolInfo, nil, &pool); ret != vk.Success {
+		return fmt.Errorf("vkCreateDescriptorPool: %d", ret)
+	}
+	b.descPool = pool
+
+	cmdPoolInfo := vk.CommandPoolCreateInfo{
+		SType:            vk.StructureTypeCommandPoolCreateInfo,
+		QueueFamilyIndex: b.queueFamily,
+		Flags:            vk.CommandPoolCreateFlags(vk.CommandPoolCreateResetCommandBufferBit),
+	}
+	var cmdPool vk.CommandPool
+	if ret := vk.CreateCommandPool(b.device, &cmdPoolInfo, nil, &cmdPool); ret != vk.Success {
+		return fmt.Errorf("vkCreateCommandPool: %d", ret)
+	}
+	b.cmdPool = cmdPool
+	return nil
+}
+
+// slimeParams mirrors the push_constant block in shaders/slime.comp.
+type slimeParams struct {
+	Seed         int64
+	X0, Z0       int32
+	MaskW, MaskH int32
+	Threshold    int32
+	_            int32 // pad Threshold to keep Seed's int64 8-byte aligned if this struct is ever embedded
+}
+
+func (b *gpuBackend) Search(world World, sec *Section, mask Mask, threshold int) []SearchResult {
+	w, h := mask.Bounds()
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	maskBuf, maskMem, err := b.newBuffer(maskBufferSize(w, h))
+	if err != nil {
+		panic(fmt.Sprintf("gpu backend: mask buffer: %v", err))
+	}
+	defer b.freeBuffer(maskBuf, maskMem)
+	b.writeBuffer(maskMem, uint32sToBytes(packMaskBits(mask, w, h)))
+
+	countBuf, countMem, err := b.newBuffer(4)
+	if err != nil {
+		panic(fmt.Sprintf("gpu backend: count buffer: %v", err))
+	}
+	defer b.freeBuffer(countBuf, countMem)
+	b.writeBuffer(countMem, make([]byte, 4))
+
+	resultsBuf, resultsMem, err := b.newBuffer(maxGPUResults * 16)
+	if err != nil {
+		panic(fmt.Sprintf("gpu backend: results buffer: %v", err))
+	}
+	defer b.freeBuffer(resultsBuf, resultsMem)
+
+	descSet, err := b.allocDescriptorSet()
+	if err != nil {
+		panic(fmt.Sprintf("gpu backend: descriptor set: %v", err))
+	}
+	// descPool holds a single set; hand it back for the next Search.
+	defer vk.ResetDescriptorPool(b.device, b.descPool, 0)
+	b.bindBuffers(descSet, maskBuf, countBuf, resultsBuf)
+
+	params := slimeParams{
+		Seed: int64(world), X0: sec.X, Z0: sec.Z,
+		MaskW: w, MaskH: h, Threshold: int32(threshold),
+	}
+	groupsX := uint32((SectionSize - w + localSize - 1) / localSize)
+	groupsZ := uint32((SectionSize - h + localSize - 1) / localSize)
+	if err := b.dispatch(descSet, params, groupsX, groupsZ); err != nil {
+		panic(fmt.Sprintf("gpu backend: dispatch: %v", err))
+	}
+
+	return b.readResults(countMem, resultsMem)
+}
+
+// packMaskBits packs mask into row-major uint32 words, ceil(w/32) per row,
+// matching the MaskBits buffer layout shaders/slime.comp expects.
+func packMaskBits(mask Mask, w, h int32) []uint32 {
+	wordsPerRow := (int(w) + 31) / 32
+	words := make([]uint32, wordsPerRow*int(h))
+	for z := int32(0); z < h; z++ {
+		for x := int32(0); x < w; x++ {
+			if mask.Query(x, z) {
+				words[int(z)*wordsPerRow+int(x)/32] |= 1 << uint(x%32)
+			}
+		}
+	}
+	return words
+}
+
+func maskBufferSize(w, h int32) int {
+	wordsPerRow := (int(w) + 31) / 32
+	return wordsPerRow * int(h) * 4
+}
+
+func bytesToUint32(b []byte) []uint32 {
+	out := make([]uint32, len(b)/4)
+	for i := range out {
+		out[i] = uint32(b[i*4]) | uint32(b[i*4+1])<<8 | uint32(b[i*4+2])<<16 | uint32(b[i*4+3])<<24
+	}
+	return out
+}
+
+func uint32sToBytes(words []uint32) []byte {
+	out := make([]byte, len(words)*4)
+	for i, w := range words {
+		out[i*4] = byte(w)
+		out[i*4+1] = byte(w >> 8)
+		out[i*4+2] = byte(w >> 16)
+		out[i*4+3] = byte(w >> 24)
+	}
+	return out
+}
+
+func le32(b []byte) uint32 {
+	return uint32(b[0]) | uint32(b[1])<<8 | uint32(b[2])<<16 | uint32(b[3])<<24
+}
+
+// newBuffer allocates a host-visible, host-coherent storage buffer. These
+// sections are small enough (a few hundred KB at most) that skipping a
+// device-local staging buffer and mapping memory directly is the simpler
+// and fast-enough choice.
+func (b *gpuBackend) newBuffer(size int) (vk.Buffer, vk.DeviceMemory, error) {
+	if size <= 0 {
+		size = 4
+	}
+	bufInfo := vk.BufferCreateInfo{
+		SType:       vk.StructureTypeBufferCreateInfo,
+		Size:        vk.DeviceSize(size),
+		Usage:       vk.BufferUsageFlags(vk.BufferUsageStorageBufferBit),
+		SharingMode: vk.SharingModeExclusive,
+	}
+	var buf vk.Buffer
+	if ret := vk.CreateBuffer(b.device, &bufInfo, nil, &buf); ret != vk.Success {
+		return nil, nil, fmt.Errorf("vkCreateBuffer: %d", ret)
+	}
+
+	var memReq vk.MemoryRequirements
+	vk.GetBufferMemoryRequirements(b.device, buf, &memReq)
+	memReq.Deref()
+
+	memType, err := b.findMemoryType(memReq.MemoryTypeBits,
+		vk.MemoryPropertyFlags(vk.MemoryPropertyHostVisibleBit|vk.MemoryPropertyHostCoherentBit))
+	if err != nil {
+		vk.DestroyBuffer(b.device, buf, nil)
+		return nil, nil, err
+	}
+
+	allocInfo := vk.MemoryAllocateInfo{
+		SType:           vk.StructureTypeMemoryAllocateInfo,
+		AllocationSize:  memReq.Size,
+		MemoryTypeIndex: memType,
+	}
+	var mem vk.DeviceMemory
+	if ret := vk.AllocateMemory(b.device, &allocInfo, nil, &mem); ret != vk.Success {
+		vk.DestroyBuffer(b.device, buf, nil)
+		return nil, nil, fmt.Errorf("vkAllocateMemory: %d", ret)
+	}
+	if ret := vk.BindBufferMemory(b.device, buf, mem, 0); ret != vk.Success {
+		return nil, nil, fmt.Errorf("vkBindBufferMemory: %d", ret)
+	}
+	return buf, mem, nil
+}
+
+func (b *gpuBackend) freeBuffer(buf vk.Buffer, mem vk.DeviceMemory) {
+	vk.DestroyBuffer(b.device, buf, nil)
+	vk.FreeMemory(b.device, mem, nil)
+}
+
+func (b *gpuBackend) findMemoryType(typeFilter uint32, props vk.MemoryPropertyFlags) (uint32, error) {
+	var memProps vk.PhysicalDeviceMemoryProperties
+	vk.GetPhysicalDeviceMemoryProperties(b.physDevice, &memProps)
+	memProps.Deref()
+	for i := uint32(0); i < memProps.MemoryTypeCount; i++ {
+		memProps.MemoryTypes[i].Deref()
+		if typeFilter&(1<<i) != 0 && memProps.MemoryTypes[i].PropertyFlags&props == props {
+			return i, nil
+		}
+	}
+	return 0, fmt.Errorf("no memory type satisfies the requested properties")
+}
+
+func (b *gpuBackend) writeBuffer(mem vk.DeviceMemory, data []byte) {
+	var ptr unsafe.Pointer
+	vk.MapMemory(b.device, mem, 0, vk.DeviceSize(len(data)), 0, &ptr)
+	dst := unsafe.Slice((*byte)(ptr), len(data))
+	copy(dst, data)
+	vk.UnmapMemory(b.device, mem)
+}
+
+func (b *gpuBackend) readBuffer(mem vk.DeviceMemory, size int) []byte {
+	var ptr unsafe.Pointer
+	vk.MapMemory(b.device, mem, 0, vk.DeviceSize(size), 0, &ptr)
+	src := unsafe.Slice((*byte)(ptr), size)
+	out := make([]byte, size)
+	copy(out, src)
+	vk.UnmapMemory(b.device, mem)
+	return out
+}
+
+func (b *gpuBackend) allocDescriptorSet() (vk.DescriptorSet, error) {
+	allocInfo := vk.DescriptorSetAllocateInfo{
+		SType:              vk.StructureTypeDescriptorSetAllocateInfo,
+		DescriptorPool:     b.descPool,
+		DescriptorSetCount: 1,
+		PSetLayouts:        []vk.DescriptorSetLayout{b.descSetLayout},
+	}
+	sets := make([]vk.DescriptorSet, 1)
+	if ret := vk.AllocateDescriptorSets(b.device, &allocInfo, &sets[0]); ret != vk.Success {
+		return nil, fmt.Errorf("vkAllocateDescriptorSets: %d", ret)
+	}
+	return sets[0], nil
+}
+
+func (b *gpuBackend) bindBuffers(set vk.DescriptorSet, mask, count, results vk.Buffer) {
+	buffers := []vk.Buffer{mask, count, results}
+	writes := make([]vk.WriteDescriptorSet, len(buffers))
+	for i, buf := range buffers {
+		writes[i] = vk.WriteDescriptorSet{
+			SType:           vk.StructureTypeWriteDescriptorSet,
+			DstSet:          set,
+			DstBinding:      uint32(i),
+			DescriptorCount: 1,
+			DescriptorType:  vk.DescriptorTypeStorageBuffer,
+			PBufferInfo:     []vk.DescriptorBufferInfo{{Buffer: buf, Offset: 0, Range: vk.DeviceSize(vk.WholeSize)}},
+		}
+	}
+	vk.UpdateDescriptorSets(b.device, uint32(len(writes)), writes, 0, nil)
+}
+
+// dispatch records and submits a single one-shot command buffer: bind the
+// slime pipeline and descriptor set, push params, dispatch one workgroup
+// per localSize x localSize tile of candidate positions, then block until
+// it's done. freeBuffer on the caller's buffers right after this returns is
+// safe because of that wait.
+func (b *gpuBackend) dispatch(set vk.DescriptorSet, params slimeParams, groupsX, groupsZ uint32) error {
+	allocInfo := vk.CommandBufferAllocateInfo{
+		SType:              vk.StructureTypeCommandBufferAllocateInfo,
+		CommandPool:        b.cmdPool,
+		Level:              vk.CommandBufferLevelPrimary,
+		CommandBufferCount: 1,
+	}
+	cmdBufs := make([]vk.CommandBuffer, 1)
+	if ret := vk.AllocateCommandBuffers(b.device, &allocInfo, cmdBufs); ret != vk.Success {
+		return fmt.Errorf("vkAllocateCommandBuffers: %d", ret)
+	}
+	cmdBuf := cmdBufs[0]
+	defer vk.FreeCommandBuffers(b.device, b.cmdPool, 1, cmdBufs)
+
+	beginInfo := vk.CommandBufferBeginInfo{
+		SType: vk.StructureTypeCommandBufferBeginInfo,
+		Flags: vk.CommandBufferUsageFlags(vk.CommandBufferUsageOneTimeSubmitBit),
+	}
+	if ret := vk.BeginCommandBuffer(cmdBuf, &beginInfo); ret != vk.Success {
+		return fmt.Errorf("vkBeginCommandBuffer: %d", ret)
+	}
+
+	vk.CmdBindPipeline(cmdBuf, vk.PipelineBindPointCompute, b.pipeline)
+	vk.CmdBindDescriptorSets(cmdBuf, vk.PipelineBindPointCompute, b.pipelineLayout, 0, 1, []vk.DescriptorSet{set}, 0, nil)
+	vk.CmdPushConstants(cmdBuf, b.pipelineLayout, vk.ShaderStageFlags(vk.ShaderStageComputeBit), 0, uint32(unsafe.Sizeof(params)), unsafe.Pointer(&params))
+	vk.CmdDispatch(cmdBuf, groupsX, groupsZ, 1)
+
+	if ret := vk.EndCommandBuffer(cmdBuf); ret != vk.Success {
+		return fmt.Errorf("vkEndCommandBuffer: %d", ret)
+	}
+
+	submitInfo := vk.SubmitInfo{
+		SType:              vk.StructureTypeSubmitInfo,
+		CommandBufferCount: 1,
+		PCommandBuffers:    []vk.CommandBuffer{cmdBuf},
+	}
+	if ret := vk.QueueSubmit(b.queue, 1, []vk.SubmitInfo{submitInfo}, nil); ret != vk.Success {
+		return fmt.Errorf("vkQueueSubmit: %d", ret)
+	}
+	if ret := vk.QueueWaitIdle(b.queue); ret != vk.Success {
+		return fmt.Errorf("vkQueueWaitIdle: %d", ret)
+	}
+	return nil
+}
+
+// readResults reads back the atomic cursor and however many result slots it
+// claims, converting each ivec4 (x, z, count, unused) into a SearchResult.
+func (b *gpuBackend) readResults(countMem, resultsMem vk.DeviceMemory) []SearchResult {
+	countBytes := b.readBuffer(countMem, 4)
+	count := int(le32(countBytes))
+	if count > maxGPUResults {
+		count = maxGPUResults
+	}
+
+	raw := b.readBuffer(resultsMem, count*16)
+	results := make([]SearchResult, count)
+	for i := 0; i < count; i++ {
+		off := i * 16
+		results[i] = SearchResult{
+			X:     int32(le32(raw[off:])),
+			Z:     int32(le32(raw[off+4:])),
+			Count: int(int32(le32(raw[off+8:]))),
+		}
+	}
+	return results
+}
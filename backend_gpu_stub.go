@@ -0,0 +1,12 @@
+//go:build !gpu
+
+package main
+
+import "fmt"
+
+// newGPUBackend reports that no GPU backend was compiled in. Build with
+// `-tags gpu` (and a Vulkan-capable driver available at runtime) to get the
+// real implementation in backend_gpu.go.
+func newGPUBackend() (Backend, error) {
+	return nil, fmt.Errorf("gpu backend not compiled in (build with -tags gpu)")
+}
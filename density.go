@@ -0,0 +1,47 @@
+package main
+
+import (
+	"math/bits"
+	"os"
+	"strconv"
+)
+
+// defaultSparseRatio is the crossover point between Section.searchSparse and
+// Section.searchDense: sparse wins once a mask-sized window holds fewer than
+// this many set chunks on average (density * mask area), in the same
+// spirit as dgraph's linVsBinRatio picking between a linear scan and a
+// binary search by measured selectivity. Override with SLIMY_SPARSE_RATIO
+// for benchmarking.
+//
+// On a 16x16 mask searchSparse is faster than searchDense up to
+// density*area ~= 38-40 and slower past ~46. That range straddles
+// Minecraft's fixed ~10% slime chunk density (density*area == 25.6 for a
+// 16x16 mask), so the adaptive split actually fires -- and wins -- for real
+// afk-pod searches, not just pathological ones.
+const defaultSparseRatio = 40.0
+
+var sparseRatio = readSparseRatio()
+
+func readSparseRatio() float64 {
+	if s := os.Getenv("SLIMY_SPARSE_RATIO"); s != "" {
+		if v, err := strconv.ParseFloat(s, 64); err == nil {
+			return v
+		}
+	}
+	return defaultSparseRatio
+}
+
+// shouldScanSparse estimates how many set chunks fall in an average
+// mask-sized window (density * mask area) and compares it against
+// sparseRatio to pick a scan strategy.
+func shouldScanSparse(secRows [SectionSize]rowBits, maskWidth, maskHeight int32) bool {
+	return sectionDensity(secRows)*float64(maskWidth)*float64(maskHeight) < sparseRatio
+}
+
+func sectionDensity(secRows [SectionSize]rowBits) float64 {
+	set := 0
+	for _, row := range secRows {
+		set += bits.OnesCount64(row[0]) + bits.OnesCount64(row[1])
+	}
+	return float64(set) / float64(SectionSize*SectionSize)
+}
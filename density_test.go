@@ -0,0 +1,125 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+// fillDensity fills sec deterministically so a density can be requested
+// directly, rather than relying on World.CalcChunk's fixed ~10% rate.
+func fillDensity(sec *Section, density float64, seed uint64) {
+	state := seed
+	for z := int32(0); z < SectionSize; z++ {
+		for x := int32(0); x < SectionSize; x++ {
+			state = state*6364136223846793005 + 1442695040888963407
+			frac := float64(state>>11) / float64(uint64(1)<<53)
+			sec.Set(x, z, frac < density)
+		}
+	}
+}
+
+// BenchmarkSectionSearchByDensity drives Section.Search (the adaptive
+// entry point, not a forced strategy) across densities chosen to actually
+// straddle defaultSparseRatio for a 16x16 mask (crossover at
+// density*256 ~= 40, i.e. density ~= 0.156): Sparse and Typical land below
+// it and take the searchSparse path, Dense lands above it and takes
+// searchDense. Typical is Minecraft's real fixed ~10% slime chunk rate, so
+// this is also the case that justifies the default actually helping typical
+// afk-pod searches, not just contrived ones.
+func BenchmarkSectionSearchByDensity(b *testing.B) {
+	cases := []struct {
+		name    string
+		density float64
+	}{
+		{"Sparse", 0.05},
+		{"Typical", 0.1},
+		{"Dense", 0.4},
+	}
+	mask := afkMask{w: 16, h: 16}
+
+	for _, c := range cases {
+		b.Run(c.name, func(b *testing.B) {
+			sec := &Section{X: 0, Z: 0}
+			fillDensity(sec, c.density, 1)
+			secRows := sec.packRows()
+			if got := shouldScanSparse(secRows, mask.w, mask.h); got != (c.density*256 < defaultSparseRatio) {
+				b.Fatalf("shouldScanSparse = %v for density %v (area*density=%v, ratio=%v)",
+					got, c.density, c.density*256, defaultSparseRatio)
+			}
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				sec.Search(mask, 1)
+			}
+		})
+	}
+}
+
+// TestShouldScanSparseCrossesOver checks that shouldScanSparse actually
+// picks both strategies somewhere in a realistic density range, rather than
+// the heuristic being unreachable at typical afk-mask sizes.
+func TestShouldScanSparseCrossesOver(t *testing.T) {
+	mask := afkMask{w: 16, h: 16}
+
+	below := &Section{X: 0, Z: 0}
+	fillDensity(below, 0.05, 1)
+	if !shouldScanSparse(below.packRows(), mask.w, mask.h) {
+		t.Errorf("shouldScanSparse(density=0.05) = false, want true (below defaultSparseRatio=%v)", defaultSparseRatio)
+	}
+
+	above := &Section{X: 0, Z: 0}
+	fillDensity(above, 0.4, 1)
+	if shouldScanSparse(above.packRows(), mask.w, mask.h) {
+		t.Errorf("shouldScanSparse(density=0.4) = true, want false (above defaultSparseRatio=%v)", defaultSparseRatio)
+	}
+}
+
+func BenchmarkSectionSearchSparseForced(b *testing.B) {
+	sec := &Section{X: 0, Z: 0}
+	fillDensity(sec, 0.01, 2)
+	secRows := sec.packRows()
+	mask := afkMask{w: 16, h: 16}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		sec.searchSparse(mask, 16, 16, secRows, 1)
+	}
+}
+
+// TestSearchSparseDenseAgree checks that searchSparse and searchDense find
+// exactly the same matches for the same section and mask, across a range of
+// densities and non-square masks, so shouldScanSparse is free to pick either
+// strategy without changing the answer.
+func TestSearchSparseDenseAgree(t *testing.T) {
+	densities := []float64{0.0, 0.01, 0.1, 0.4, 1.0}
+	masks := []afkMask{
+		{w: 16, h: 16},
+		{w: 4, h: 64},
+		{w: 64, h: 4},
+		{w: 9, h: 3},
+	}
+
+	for _, density := range densities {
+		for _, mask := range masks {
+			sec := &Section{X: 0, Z: 0}
+			fillDensity(sec, density, uint64(density*1000)+1)
+			secRows := sec.packRows()
+
+			sparse := sec.searchSparse(mask, mask.w, mask.h, secRows, 1)
+			dense := sec.searchDense(mask, mask.w, mask.h, secRows, 1)
+			if !reflect.DeepEqual(sparse, dense) {
+				t.Fatalf("density=%v mask=%+v: searchSparse and searchDense disagree:\nsparse: %v\ndense:  %v",
+					density, mask, sparse, dense)
+			}
+		}
+	}
+}
+
+func BenchmarkSectionSearchDenseForced(b *testing.B) {
+	sec := &Section{X: 0, Z: 0}
+	fillDensity(sec, 0.01, 2)
+	secRows := sec.packRows()
+	mask := afkMask{w: 16, h: 16}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		sec.searchDense(mask, 16, 16, secRows, 1)
+	}
+}
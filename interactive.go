@@ -0,0 +1,83 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+
+	"github.com/vktec/slimy/tui"
+)
+
+// RunInteractiveSearch runs a search and browses its results live in a
+// terminal UI, instead of waiting for the search to finish. It backs the
+// `-i`/`--interactive` CLI flag.
+func RunInteractiveSearch(w World, x0, z0, x1, z1 int32, threshold int, mask Mask, journalPath string) error {
+	resultCh, progressCh, err := w.SearchStream(x0, z0, x1, z1, threshold, mask, journalPath)
+	if err != nil {
+		return err
+	}
+
+	tuiResults := make(chan tui.Result)
+	go func() {
+		defer close(tuiResults)
+		for r := range resultCh {
+			tuiResults <- tui.Result{Count: r.Count, X: r.X, Z: r.Z}
+		}
+	}()
+
+	tuiProgress := make(chan tui.Progress)
+	go func() {
+		defer close(tuiProgress)
+		for p := range progressCh {
+			tuiProgress <- tui.Progress{Done: p.Done, Total: p.Total}
+		}
+	}()
+
+	return tui.Run(tui.Options{
+		Results:  tuiResults,
+		Progress: tuiProgress,
+		Save:     saveVisibleResults,
+		Copy:     copyToClipboard,
+	})
+}
+
+func saveVisibleResults(path string, results []tui.Result) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w := bufio.NewWriter(f)
+	for _, r := range results {
+		fmt.Fprintf(w, "%d\t%d\t%d\n", r.Count, r.X, r.Z)
+	}
+	return w.Flush()
+}
+
+// copyToClipboard tries the usual clipboard tools in turn, falling back to
+// printing the command if none are available (e.g. over SSH).
+func copyToClipboard(cmd string) error {
+	for _, tool := range [][]string{
+		{"wl-copy"},
+		{"xclip", "-selection", "clipboard"},
+		{"pbcopy"},
+	} {
+		c := exec.Command(tool[0], tool[1:]...)
+		stdin, err := c.StdinPipe()
+		if err != nil {
+			continue
+		}
+		if err := c.Start(); err != nil {
+			continue
+		}
+		stdin.Write([]byte(cmd))
+		stdin.Close()
+		if c.Wait() == nil {
+			return nil
+		}
+	}
+	fmt.Println(cmd)
+	return nil
+}
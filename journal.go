@@ -0,0 +1,212 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"os"
+	"sync"
+)
+
+// JournalHeader identifies the search a journal belongs to. Resuming a
+// journal whose header doesn't match the requested search is refused, since
+// the recorded progress would otherwise be meaningless.
+type JournalHeader struct {
+	Seed           int64
+	X0, Z0, X1, Z1 int32
+	Threshold      int
+	MaskHash       string
+}
+
+// SearchJournal is a checkpoint log for World.Search, recording which
+// sections have been computed and what they found so a search over a huge
+// coordinate range can be interrupted and resumed without redoing work.
+type SearchJournal interface {
+	// Header returns the parameters the journal was opened with, or
+	// ok == false if the journal is new and has no header yet.
+	Header() (header JournalHeader, ok bool)
+	// WriteHeader records the search parameters. Must be called exactly
+	// once, before any call to MarkDone.
+	WriteHeader(header JournalHeader) error
+	// Done reports whether the section at (x, z) has already completed.
+	Done(x, z int32) bool
+	// MarkDone records that the section at (x, z) completed with the given
+	// results and flushes the record to disk. Safe for concurrent use.
+	MarkDone(x, z int32, results []SearchResult) error
+	// Results returns every result recorded so far, in the order they were
+	// written.
+	Results() []SearchResult
+	// Entries returns the completed sections recorded so far, each with the
+	// results found in it. Used by journal merging, which needs to dedupe
+	// by section rather than by individual result.
+	Entries() []JournalEntry
+	// Close flushes and closes the underlying storage.
+	Close() error
+}
+
+// JournalEntry is a single completed section and the results found in it.
+type JournalEntry struct {
+	X, Z    int32
+	Results []SearchResult
+}
+
+// journalRecord is the on-disk representation of a single journal line:
+// either a header or a completed section, never both.
+type journalRecord struct {
+	Header  *JournalHeader `json:"header,omitempty"`
+	Section *sectionRecord `json:"section,omitempty"`
+}
+
+type sectionRecord struct {
+	X, Z    int32
+	Results []SearchResult
+}
+
+func sectionKey(x, z int32) int64 {
+	return int64(x)<<32 | int64(uint32(z))
+}
+
+// maskHash summarises a mask's shape so a journal can detect being resumed
+// against a different mask. It isn't cryptographic, just cheap and stable.
+func maskHash(mask Mask) string {
+	w, h := mask.Bounds()
+	hsh := fnv.New64a()
+	fmt.Fprintf(hsh, "%d,%d:", w, h)
+	row := make([]byte, w)
+	for z := int32(0); z < h; z++ {
+		for x := int32(0); x < w; x++ {
+			row[x] = 0
+			if mask.Query(x, z) {
+				row[x] = 1
+			}
+		}
+		hsh.Write(row)
+	}
+	return fmt.Sprintf("%x", hsh.Sum64())
+}
+
+// fileJournal is the file-backed SearchJournal implementation: newline
+// delimited JSON records, replayed on open and appended to thereafter.
+type fileJournal struct {
+	mu      sync.Mutex
+	f       *os.File
+	enc     *json.Encoder
+	header  JournalHeader
+	hasHdr  bool
+	done    map[int64]bool
+	entries []JournalEntry
+}
+
+// OpenSearchJournal opens the journal at path, creating it if it doesn't
+// exist, and replays any records it already contains.
+func OpenSearchJournal(path string) (SearchJournal, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("open journal: %w", err)
+	}
+
+	j := &fileJournal{f: f, done: make(map[int64]bool)}
+	if err := j.replay(); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("replay journal: %w", err)
+	}
+	if _, err := f.Seek(0, os.SEEK_END); err != nil {
+		f.Close()
+		return nil, err
+	}
+	j.enc = json.NewEncoder(f)
+	return j, nil
+}
+
+// replay reads every complete record from the start of the journal,
+// rebuilding header/done/entries from them, and truncates away anything
+// past the last complete record. A journal that was being appended to when
+// its writer was killed (crash, Ctrl-C, power loss -- the entire point of
+// this feature) ends with a trailing line that never got its newline: that
+// interrupted record is discarded rather than failing the reopen, exactly
+// like a WAL recovering from a torn write. The section it belonged to
+// simply gets recomputed, same as any section that hadn't started yet.
+func (j *fileJournal) replay() error {
+	r := bufio.NewReaderSize(j.f, 64*1024)
+	var offset int64
+	for {
+		line, err := r.ReadBytes('\n')
+		if len(line) == 0 {
+			break
+		}
+		if err == io.EOF {
+			// No trailing newline: this record never finished writing.
+			// Stop here and truncate it away below.
+			break
+		}
+
+		var rec journalRecord
+		if perr := json.Unmarshal(bytes.TrimSuffix(line, []byte("\n")), &rec); perr != nil {
+			return fmt.Errorf("corrupt journal record at offset %d: %w", offset, perr)
+		}
+		switch {
+		case rec.Header != nil:
+			j.header, j.hasHdr = *rec.Header, true
+		case rec.Section != nil:
+			j.done[sectionKey(rec.Section.X, rec.Section.Z)] = true
+			j.entries = append(j.entries, JournalEntry{rec.Section.X, rec.Section.Z, rec.Section.Results})
+		}
+		offset += int64(len(line))
+	}
+	return j.f.Truncate(offset)
+}
+
+func (j *fileJournal) Header() (JournalHeader, bool) {
+	return j.header, j.hasHdr
+}
+
+func (j *fileJournal) WriteHeader(header JournalHeader) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if err := j.enc.Encode(journalRecord{Header: &header}); err != nil {
+		return err
+	}
+	j.header, j.hasHdr = header, true
+	return j.f.Sync()
+}
+
+func (j *fileJournal) Done(x, z int32) bool {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.done[sectionKey(x, z)]
+}
+
+func (j *fileJournal) MarkDone(x, z int32, results []SearchResult) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	rec := journalRecord{Section: &sectionRecord{X: x, Z: z, Results: results}}
+	if err := j.enc.Encode(rec); err != nil {
+		return err
+	}
+	j.done[sectionKey(x, z)] = true
+	j.entries = append(j.entries, JournalEntry{x, z, results})
+	return j.f.Sync()
+}
+
+func (j *fileJournal) Results() []SearchResult {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	var results []SearchResult
+	for _, e := range j.entries {
+		results = append(results, e.Results...)
+	}
+	return results
+}
+
+func (j *fileJournal) Entries() []JournalEntry {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return append([]JournalEntry(nil), j.entries...)
+}
+
+func (j *fileJournal) Close() error {
+	return j.f.Close()
+}
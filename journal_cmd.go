@@ -0,0 +1,113 @@
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+// RunJournalCommand implements the `slimy journal` subcommand: inspecting a
+// journal's progress, or merging journals produced by several machines that
+// each scanned a disjoint slice of the same search.
+//
+//	slimy journal inspect <path>
+//	slimy journal merge <dest> <src>...
+func RunJournalCommand(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: slimy journal <inspect|merge> ...")
+	}
+
+	switch args[0] {
+	case "inspect":
+		if len(args) != 2 {
+			return fmt.Errorf("usage: slimy journal inspect <path>")
+		}
+		return journalInspect(args[1])
+	case "merge":
+		if len(args) < 3 {
+			return fmt.Errorf("usage: slimy journal merge <dest> <src>...")
+		}
+		return journalMerge(args[1], args[2:])
+	default:
+		return fmt.Errorf("unknown journal subcommand %q", args[0])
+	}
+}
+
+func journalInspect(path string) error {
+	j, err := OpenSearchJournal(path)
+	if err != nil {
+		return err
+	}
+	defer j.Close()
+
+	header, ok := j.Header()
+	if !ok {
+		fmt.Println("journal has no header (nothing recorded yet)")
+		return nil
+	}
+	results := j.Results()
+	fmt.Printf("seed:      %d\n", header.Seed)
+	fmt.Printf("bounds:    (%d,%d)-(%d,%d)\n", header.X0, header.Z0, header.X1, header.Z1)
+	fmt.Printf("threshold: %d\n", header.Threshold)
+	fmt.Printf("mask hash: %s\n", header.MaskHash)
+	fmt.Printf("results so far: %d\n", len(results))
+	return nil
+}
+
+// journalMerge combines the progress of several journals that each scanned a
+// disjoint slice of the same search (e.g. one per machine dividing the
+// region) into dest. All journals, including dest if it already exists, must
+// share the same header.
+func journalMerge(dest string, srcs []string) error {
+	var header JournalHeader
+	var hasHeader bool
+
+	if _, err := os.Stat(dest); err == nil {
+		j, err := OpenSearchJournal(dest)
+		if err != nil {
+			return err
+		}
+		header, hasHeader = j.Header()
+		j.Close()
+	}
+
+	out, err := OpenSearchJournal(dest)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	for _, path := range srcs {
+		in, err := OpenSearchJournal(path)
+		if err != nil {
+			return fmt.Errorf("opening %s: %w", path, err)
+		}
+
+		srcHeader, ok := in.Header()
+		if !ok {
+			in.Close()
+			continue
+		}
+		if !hasHeader {
+			header, hasHeader = srcHeader, true
+			if err := out.WriteHeader(header); err != nil {
+				in.Close()
+				return err
+			}
+		} else if srcHeader != header {
+			in.Close()
+			return fmt.Errorf("%s: journal header does not match %s", path, dest)
+		}
+
+		for _, entry := range in.Entries() {
+			if out.Done(entry.X, entry.Z) {
+				continue
+			}
+			if err := out.MarkDone(entry.X, entry.Z, entry.Results); err != nil {
+				in.Close()
+				return err
+			}
+		}
+		in.Close()
+	}
+	return nil
+}
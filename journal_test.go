@@ -0,0 +1,160 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func testHeader() JournalHeader {
+	return JournalHeader{Seed: 1, X0: 0, Z0: 0, X1: 256, Z1: 256, Threshold: 1, MaskHash: "abc"}
+}
+
+func TestJournalRoundtrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal")
+
+	j, err := OpenSearchJournal(path)
+	if err != nil {
+		t.Fatalf("OpenSearchJournal: %v", err)
+	}
+	if err := j.WriteHeader(testHeader()); err != nil {
+		t.Fatalf("WriteHeader: %v", err)
+	}
+	results := []SearchResult{{Count: 3, X: 1, Z: 2}}
+	if err := j.MarkDone(1, 2, results); err != nil {
+		t.Fatalf("MarkDone: %v", err)
+	}
+	if err := j.MarkDone(3, 4, nil); err != nil {
+		t.Fatalf("MarkDone: %v", err)
+	}
+	if err := j.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	j2, err := OpenSearchJournal(path)
+	if err != nil {
+		t.Fatalf("reopen OpenSearchJournal: %v", err)
+	}
+	defer j2.Close()
+
+	header, ok := j2.Header()
+	if !ok || header != testHeader() {
+		t.Fatalf("Header() = %+v, %v; want %+v, true", header, ok, testHeader())
+	}
+	if !j2.Done(1, 2) || !j2.Done(3, 4) {
+		t.Fatalf("Done() false for a section that was marked done before reopening")
+	}
+	if j2.Done(5, 6) {
+		t.Fatalf("Done() true for a section that was never marked done")
+	}
+	if got := j2.Results(); !reflect.DeepEqual(got, results) {
+		t.Fatalf("Results() = %v, want %v", got, results)
+	}
+}
+
+// TestJournalReplayTruncatedTrailingRecord checks that a journal left with
+// an incomplete final line -- what a crash or kill -9 mid-write produces --
+// reopens successfully with everything before it intact, instead of failing
+// with a JSON decode error.
+func TestJournalReplayTruncatedTrailingRecord(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal")
+
+	j, err := OpenSearchJournal(path)
+	if err != nil {
+		t.Fatalf("OpenSearchJournal: %v", err)
+	}
+	if err := j.WriteHeader(testHeader()); err != nil {
+		t.Fatalf("WriteHeader: %v", err)
+	}
+	if err := j.MarkDone(1, 2, []SearchResult{{Count: 5, X: 1, Z: 2}}); err != nil {
+		t.Fatalf("MarkDone: %v", err)
+	}
+	if err := j.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	// Simulate a crash partway through writing the next record: a partial
+	// line with no trailing newline appended to an otherwise well-formed
+	// journal.
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatalf("open for append: %v", err)
+	}
+	if _, err := f.WriteString(`{"section":{"X":7,"Z":8,"Resul`); err != nil {
+		t.Fatalf("write partial record: %v", err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+
+	j2, err := OpenSearchJournal(path)
+	if err != nil {
+		t.Fatalf("OpenSearchJournal with truncated trailing record: %v", err)
+	}
+	defer j2.Close()
+
+	if header, ok := j2.Header(); !ok || header != testHeader() {
+		t.Fatalf("Header() = %+v, %v; want %+v, true", header, ok, testHeader())
+	}
+	if !j2.Done(1, 2) {
+		t.Fatalf("Done(1, 2) = false; the complete record before the truncated one was lost")
+	}
+	if j2.Done(7, 8) {
+		t.Fatalf("Done(7, 8) = true; the truncated record should have been discarded")
+	}
+
+	// Resuming must also leave the journal in a state where further writes
+	// are framed correctly, not appended onto the dangling partial line.
+	if err := j2.MarkDone(7, 8, []SearchResult{{Count: 2, X: 7, Z: 8}}); err != nil {
+		t.Fatalf("MarkDone after resume: %v", err)
+	}
+	if err := j2.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	j3, err := OpenSearchJournal(path)
+	if err != nil {
+		t.Fatalf("OpenSearchJournal after resumed write: %v", err)
+	}
+	defer j3.Close()
+	if !j3.Done(1, 2) || !j3.Done(7, 8) {
+		t.Fatalf("Done() missing a section recorded across the resume")
+	}
+}
+
+func TestJournalMerge(t *testing.T) {
+	dir := t.TempDir()
+	a := filepath.Join(dir, "a")
+	b := filepath.Join(dir, "b")
+	dest := filepath.Join(dir, "dest")
+
+	ja, _ := OpenSearchJournal(a)
+	ja.WriteHeader(testHeader())
+	ja.MarkDone(0, 0, []SearchResult{{Count: 4, X: 0, Z: 0}})
+	ja.Close()
+
+	jb, _ := OpenSearchJournal(b)
+	jb.WriteHeader(testHeader())
+	jb.MarkDone(1, 1, []SearchResult{{Count: 6, X: 1, Z: 1}})
+	jb.Close()
+
+	if err := journalMerge(dest, []string{a, b}); err != nil {
+		t.Fatalf("journalMerge: %v", err)
+	}
+
+	jd, err := OpenSearchJournal(dest)
+	if err != nil {
+		t.Fatalf("OpenSearchJournal(dest): %v", err)
+	}
+	defer jd.Close()
+
+	if !jd.Done(0, 0) || !jd.Done(1, 1) {
+		t.Fatalf("merged journal is missing a section from one of its sources")
+	}
+	want := []SearchResult{{Count: 4, X: 0, Z: 0}, {Count: 6, X: 1, Z: 1}}
+	got := jd.Results()
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Results() = %v, want %v", got, want)
+	}
+}
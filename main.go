@@ -0,0 +1,52 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+)
+
+// main is the `slimy` CLI: a plain search over a rectangular mask (optionally
+// browsed live with -i/--interactive, see RunInteractiveSearch), or the
+// `slimy journal` subcommand for inspecting/merging journal files (see
+// RunJournalCommand). Run with -h for the full flag list.
+func main() {
+	if err := run(os.Args[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, "slimy:", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string) error {
+	if len(args) > 0 && args[0] == "journal" {
+		return RunJournalCommand(args[1:])
+	}
+
+	fs := flag.NewFlagSet("slimy", flag.ContinueOnError)
+	seed := fs.Int64("seed", 0, "world seed")
+	x0 := fs.Int("x0", -1000, "search region min x, in chunks")
+	z0 := fs.Int("z0", -1000, "search region min z, in chunks")
+	x1 := fs.Int("x1", 1000, "search region max x, in chunks")
+	z1 := fs.Int("z1", 1000, "search region max z, in chunks")
+	maskW := fs.Int("mask-w", 16, "search mask width, in chunks")
+	maskH := fs.Int("mask-h", 16, "search mask height, in chunks")
+	threshold := fs.Int("threshold", 1, "minimum slime chunk count to report")
+	journalPath := fs.String("journal", "", "journal file to checkpoint/resume this search (optional)")
+	interactive := fs.Bool("i", false, "browse results live in a terminal UI as the search runs")
+	fs.BoolVar(interactive, "interactive", false, "alias for -i")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	world := World(*seed)
+	mask := RectMask{W: int32(*maskW), H: int32(*maskH)}
+
+	if *interactive {
+		return RunInteractiveSearch(world, int32(*x0), int32(*z0), int32(*x1), int32(*z1), *threshold, mask, *journalPath)
+	}
+
+	for _, r := range world.Search(int32(*x0), int32(*z0), int32(*x1), int32(*z1), *threshold, mask, *journalPath) {
+		fmt.Printf("count=%-3d x=%-8d z=%-8d\n", r.Count, r.X, r.Z)
+	}
+	return nil
+}
@@ -0,0 +1,21 @@
+package main
+
+// Mask describes the shape of a search: a bounded w x h footprint and which
+// positions within it count. Bounds must return the same (w, h) on every
+// call; Query is only ever called with 0 <= x < w and 0 <= z < h.
+type Mask interface {
+	Bounds() (w, h int32)
+	Query(x, z int32) bool
+}
+
+// RectMask is a solid rectangular mask: every position in [0,w)x[0,h)
+// counts, nothing else does. It's the simplest Mask implementation, used by
+// the CLI's -mask-w/-mask-h flags; richer shapes (rings, unions, masks
+// loaded from a file) can be added later without touching the search or
+// journal code, which only depend on the Mask interface.
+type RectMask struct {
+	W, H int32
+}
+
+func (m RectMask) Bounds() (int32, int32) { return m.W, m.H }
+func (m RectMask) Query(x, z int32) bool  { return x >= 0 && x < m.W && z >= 0 && z < m.H }
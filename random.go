@@ -0,0 +1,44 @@
+package main
+
+// Random is a reimplementation of java.util.Random's linear congruential
+// generator, since CalcChunk's slime-chunk seed is derived and consumed the
+// same way the Java edition does. Only the pieces slimy needs (NextInt) are
+// implemented.
+type Random struct {
+	seed int64
+}
+
+const (
+	randMultiplier = 0x5DEECE66D
+	randAddend     = 0xB
+	randMask       = (1 << 48) - 1
+)
+
+// NewRandom returns a Random seeded the same way java.util.Random scrambles
+// its seed.
+func NewRandom(seed int64) *Random {
+	return &Random{seed: (seed ^ randMultiplier) & randMask}
+}
+
+// next advances the generator and returns the top bits-many bits of the new
+// state, matching java.util.Random.next.
+func (r *Random) next(bits uint) int32 {
+	r.seed = (r.seed*randMultiplier + randAddend) & randMask
+	return int32(r.seed >> (48 - bits))
+}
+
+// NextInt returns a pseudorandom value in [0, bound), matching
+// java.util.Random.nextInt(int) bit-for-bit, including its rejection loop
+// for bounds that aren't a power of two.
+func (r *Random) NextInt(bound int32) int32 {
+	if bound&-bound == bound { // bound is a power of 2
+		return int32((int64(bound) * int64(r.next(31))) >> 31)
+	}
+	for {
+		bits := r.next(31)
+		val := bits % bound
+		if bits-val+(bound-1) >= 0 {
+			return val
+		}
+	}
+}
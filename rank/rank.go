@@ -0,0 +1,34 @@
+// Package rank holds the one ordering search results are ranked by, shared
+// between World.Search (package main) and the tui result browser so the two
+// can't drift into disagreeing about which result is "better" the way
+// needSwap and resultLess once did.
+package rank
+
+// Point is the handful of fields a result is ranked on, independent of
+// whichever concrete result type (SearchResult, tui.Result) a caller has.
+type Point struct {
+	Count int
+	X, Z  int32
+}
+
+// Less reports whether a ranks strictly before b: highest count first, then
+// nearest to the origin, then position, purely to break ties so we get
+// consistent ordering. Distance is compared in int64 so results far enough
+// out that X*X+Z*Z overflows int32 (beyond +-46340 or so) still sort
+// correctly.
+func Less(a, b Point) bool {
+	if a.Count != b.Count {
+		return a.Count > b.Count
+	}
+
+	ad2 := int64(a.X)*int64(a.X) + int64(a.Z)*int64(a.Z)
+	bd2 := int64(b.X)*int64(b.X) + int64(b.Z)*int64(b.Z)
+	if ad2 != bd2 {
+		return ad2 < bd2
+	}
+
+	if a.X != b.X {
+		return a.X < b.X
+	}
+	return a.Z < b.Z
+}
@@ -0,0 +1,37 @@
+package rank
+
+import "testing"
+
+func TestLessOrdersByCountThenDistanceThenPosition(t *testing.T) {
+	cases := []struct {
+		name string
+		a, b Point
+		want bool
+	}{
+		{"higher count wins", Point{Count: 2}, Point{Count: 1}, true},
+		{"lower count loses", Point{Count: 1}, Point{Count: 2}, false},
+		{"nearer wins on tied count", Point{Count: 1, X: 1}, Point{Count: 1, X: 10}, true},
+		{"tiebreak by X", Point{Count: 1, X: -1}, Point{Count: 1, X: 1}, true},
+		{"tiebreak by Z", Point{Count: 1, X: 1, Z: -1}, Point{Count: 1, X: 1, Z: 1}, true},
+		{"equal points are not less", Point{Count: 1, X: 2, Z: 3}, Point{Count: 1, X: 2, Z: 3}, false},
+	}
+	for _, c := range cases {
+		if got := Less(c.a, c.b); got != c.want {
+			t.Errorf("%s: Less(%+v, %+v) = %v, want %v", c.name, c.a, c.b, got, c.want)
+		}
+	}
+}
+
+// TestLessDoesNotOverflow checks that results far enough out for X*X+Z*Z to
+// overflow int32 (beyond +-46340 or so) still compare correctly, since the
+// distance is computed in int64.
+func TestLessDoesNotOverflow(t *testing.T) {
+	near := Point{Count: 1, X: 100000, Z: 0}
+	far := Point{Count: 1, X: 100000, Z: 100000}
+	if !Less(near, far) {
+		t.Errorf("Less(%+v, %+v) = false, want true (near should rank before far)", near, far)
+	}
+	if Less(far, near) {
+		t.Errorf("Less(%+v, %+v) = true, want false", far, near)
+	}
+}
@@ -2,8 +2,12 @@ package main
 
 import (
 	"fmt"
+	"math/bits"
 	"runtime"
 	"sync"
+	"sync/atomic"
+
+	"github.com/vktec/slimy/rank"
 )
 
 const SectionSize = 128
@@ -22,16 +26,84 @@ func (w World) CalcChunk(x_, z_ int32) bool {
 	return r.NextInt(10) == 0
 }
 
-func (w World) Search(x0, z0, x1, z1 int32, threshold int, mask Mask) []SearchResult {
+// Search scans [x0,z0)-[x1,z1) for sections matching mask at or above
+// threshold. If journalPath is non-empty, progress is checkpointed to that
+// file as sections complete, so an interrupted search can be resumed by
+// calling Search again with the same parameters and journal path.
+func (w World) Search(x0, z0, x1, z1 int32, threshold int, mask Mask, journalPath string) []SearchResult {
+	resultCh, progressCh, err := w.SearchStream(x0, z0, x1, z1, threshold, mask, journalPath)
+	if err != nil {
+		panic(err)
+	}
+	go func() {
+		for range progressCh {
+		}
+	}()
+
+	var results []SearchResult
+	for result := range resultCh {
+		i := len(results)
+		results = append(results, result)
+		for ; i > 0 && needSwap(results[i-1], results[i]); i-- {
+			results[i-1], results[i] = results[i], results[i-1]
+		}
+	}
+	return results
+}
+
+// SearchProgress reports how many of the sections covering a search have
+// been computed so far, for driving progress bars in interactive tools.
+type SearchProgress struct {
+	Done, Total int
+}
+
+// SearchStream is like Search, but returns channels that stream results and
+// progress as the search runs instead of blocking until it's done. It's the
+// backend for tools that want to show results while a large search is still
+// in flight, such as the interactive TUI browser. Results arrive in
+// whatever order their sections complete, unsorted.
+func (w World) SearchStream(x0, z0, x1, z1 int32, threshold int, mask Mask, journalPath string) (<-chan SearchResult, <-chan SearchProgress, error) {
 	mw, mh := mask.Bounds()
 	if mw >= SectionSize || mh >= SectionSize {
 		panic("Mask bounds exceed section size")
 	}
 
+	var journal SearchJournal
+	if journalPath != "" {
+		var err error
+		journal, err = OpenSearchJournal(journalPath)
+		if err != nil {
+			return nil, nil, fmt.Errorf("opening search journal: %w", err)
+		}
+
+		header := JournalHeader{int64(w), x0, z0, x1, z1, threshold, maskHash(mask)}
+		if prev, ok := journal.Header(); ok {
+			if prev != header {
+				journal.Close()
+				return nil, nil, fmt.Errorf("journal does not match this search's parameters")
+			}
+		} else if err := journal.WriteHeader(header); err != nil {
+			journal.Close()
+			return nil, nil, fmt.Errorf("writing journal header: %w", err)
+		}
+	}
+
+	backend, err := SelectBackend()
+	if err != nil {
+		if journal != nil {
+			journal.Close()
+		}
+		return nil, nil, fmt.Errorf("selecting backend: %w", err)
+	}
+
+	total, alreadyDone := countSections(x0, z0, x1, z1, mask, journal)
+
 	sectionCh := make(chan *Section, 8)
-	resultCh := make(chan []SearchResult, 8)
+	batchCh := make(chan []SearchResult, 8)
+	progressCh := make(chan SearchProgress, 8)
 	wgroup := new(sync.WaitGroup)
-	ctx := searchContext{w, threshold, mask, wgroup, sectionCh, resultCh}
+	counter := int32(alreadyDone)
+	ctx := searchContext{w, threshold, mask, backend, journal, &counter, total, progressCh, wgroup, sectionCh, batchCh}
 	go ctx.sendSections(x0, z0, x1, z1)
 
 	workerCount := runtime.GOMAXPROCS(0)
@@ -40,41 +112,54 @@ func (w World) Search(x0, z0, x1, z1 int32, threshold int, mask Mask) []SearchRe
 		go ctx.search()
 	}
 
-	var results []SearchResult
-	for sectionResults := range resultCh {
-		start := len(results)
-		results = append(results, sectionResults...)
-		for i := start; i < len(results); i++ {
-			for j := i; j > 0; j-- {
-				if needSwap(results[j-1], results[j]) {
-					results[j-1], results[j] = results[j], results[j-1]
-				} else {
-					break
-				}
+	resultCh := make(chan SearchResult, 8)
+	go func() {
+		defer close(resultCh)
+		if journal != nil {
+			defer journal.Close()
+			for _, result := range journal.Results() {
+				resultCh <- result
 			}
 		}
-	}
-	return results
+		for batch := range batchCh {
+			for _, result := range batch {
+				resultCh <- result
+			}
+		}
+	}()
+
+	return resultCh, progressCh, nil
 }
 
-func needSwap(a, b SearchResult) bool {
-	// First sort by count
-	if a.Count != b.Count {
-		return a.Count < b.Count
+// countSections returns the total number of sections a search over
+// [x0,z0)-[x1,z1) will cover, and how many of those a journal (if any)
+// already has results for.
+func countSections(x0, z0, x1, z1 int32, mask Mask, journal SearchJournal) (total, alreadyDone int) {
+	if x0 > x1 {
+		x0, x1 = x1, x0
 	}
-
-	// Then by distance
-	ad2 := a.X*a.X + a.Z*a.Z
-	bd2 := b.X*b.X + b.Z*b.Z
-	if ad2 != bd2 {
-		return ad2 > bd2
+	if z0 > z1 {
+		z0, z1 = z1, z0
 	}
+	mx, mz := mask.Bounds()
+	shiftX := SectionSize - mx + 1
+	shiftZ := SectionSize - mz + 1
 
-	// Then by position, purely to break ties so we get consistent ordering
-	if a.X != b.X {
-		return a.X > b.X
+	for x := x0; x < x1; x += shiftX {
+		for z := z0; z < z1; z += shiftZ {
+			total++
+			if journal != nil && journal.Done(x, z) {
+				alreadyDone++
+			}
+		}
 	}
-	return a.Z > b.Z
+	return total, alreadyDone
+}
+
+// needSwap reports whether a and b are out of order in results, which is
+// built up by insertion sort: a belongs after b iff b ranks before a.
+func needSwap(a, b SearchResult) bool {
+	return rank.Less(rank.Point{Count: b.Count, X: b.X, Z: b.Z}, rank.Point{Count: a.Count, X: a.X, Z: a.Z})
 }
 
 type SearchResult struct {
@@ -83,12 +168,17 @@ type SearchResult struct {
 }
 
 type searchContext struct {
-	world     World
-	threshold int
-	mask      Mask
-	wgroup    *sync.WaitGroup
-	sectionCh chan *Section
-	resultCh  chan []SearchResult
+	world      World
+	threshold  int
+	mask       Mask
+	backend    Backend
+	journal    SearchJournal // nil if the search isn't journaled
+	done       *int32        // atomically incremented as sections complete
+	total      int
+	progressCh chan SearchProgress
+	wgroup     *sync.WaitGroup
+	sectionCh  chan *Section
+	resultCh   chan []SearchResult
 }
 
 func (ctx searchContext) sendSections(x0, z0, x1, z1 int32) {
@@ -105,6 +195,9 @@ func (ctx searchContext) sendSections(x0, z0, x1, z1 int32) {
 
 	for x := x0; x < x1; x += shiftX {
 		for z := z0; z < z1; z += shiftZ {
+			if ctx.journal != nil && ctx.journal.Done(x, z) {
+				continue
+			}
 			ctx.sectionCh <- &Section{X: x, Z: z}
 		}
 	}
@@ -112,12 +205,26 @@ func (ctx searchContext) sendSections(x0, z0, x1, z1 int32) {
 
 	ctx.wgroup.Wait()
 	close(ctx.resultCh)
+	close(ctx.progressCh)
 }
 
 func (ctx searchContext) search() {
 	for sec := range ctx.sectionCh {
-		sec.Compute(ctx.world)
-		results := sec.Search(ctx.mask, ctx.threshold)
+		results := ctx.backend.Search(ctx.world, sec, ctx.mask, ctx.threshold)
+		if ctx.journal != nil {
+			if err := ctx.journal.MarkDone(sec.X, sec.Z, results); err != nil {
+				panic(fmt.Sprintf("slimy: writing journal: %v", err))
+			}
+		}
+
+		done := int(atomic.AddInt32(ctx.done, 1))
+		select {
+		case ctx.progressCh <- SearchProgress{done, ctx.total}:
+		default:
+			// The consumer isn't keeping up; progress ticks are a
+			// best-effort UI hint, so drop rather than block the search.
+		}
+
 		if len(results) > 0 {
 			ctx.resultCh <- results
 		}
@@ -138,16 +245,34 @@ func (sec *Section) Compute(world World) {
 	}
 }
 
+// Search finds every window matching mask at or above threshold. It picks
+// between a dense scan (test every window) and a sparse scan (fan out from
+// each set chunk to the windows it could contribute to) based on the
+// section's measured density; see shouldScanSparse.
 func (sec *Section) Search(mask Mask, threshold int) (results []SearchResult) {
 	w, h := mask.Bounds()
+	secRows := sec.packRows()
+
+	if shouldScanSparse(secRows, w, h) {
+		return sec.searchSparse(mask, w, h, secRows, threshold)
+	}
+	return sec.searchDense(mask, w, h, secRows, threshold)
+}
+
+// searchDense tests every candidate window with a per-row popcount. Cheap
+// per window, so it's the better choice once a meaningful fraction of
+// windows are expected to contain a match.
+func (sec *Section) searchDense(mask Mask, w, h int32, secRows [SectionSize]rowBits, threshold int) (results []SearchResult) {
 	offX, offZ := sec.X+w/2, sec.Z+h/2
 	x1, z1 := SectionSize-w, SectionSize-h
+	maskRows := packMaskRows(mask, w, h)
 
 	for z := int32(0); z < z1; z++ {
 		for x := int32(0); x < x1; x++ {
-			// TODO: avoid checking the full mask area every time
-			//       This can be done by adding the new and subtracting the old chunks
-			count := sec.CheckMask(x, z, mask)
+			count := 0
+			for dz := int32(0); dz < h; dz++ {
+				count += popcountAnd(windowBits(secRows[z+dz], x), maskRows[dz])
+			}
 			if count >= threshold {
 				results = append(results, SearchResult{count, x + offX, z + offZ})
 			}
@@ -156,16 +281,122 @@ func (sec *Section) Search(mask Mask, threshold int) (results []SearchResult) {
 	return results
 }
 
+// searchSparse scatters each set chunk's contribution to the windows it
+// falls within, instead of testing every window from scratch. Cheaper than
+// searchDense when sections are sparse enough that most windows contain no
+// set chunks at all.
+func (sec *Section) searchSparse(mask Mask, w, h int32, secRows [SectionSize]rowBits, threshold int) (results []SearchResult) {
+	offX, offZ := sec.X+w/2, sec.Z+h/2
+	x1, z1 := SectionSize-w, SectionSize-h
+	maskRows := packMaskRows(mask, w, h)
+
+	counts := make([][]int32, z1)
+	for i := range counts {
+		counts[i] = make([]int32, x1)
+	}
+
+	for z := int32(0); z < SectionSize; z++ {
+		for word := 0; word < len(secRows[z]); word++ {
+			bitmap := secRows[z][word]
+			for bitmap != 0 {
+				x := int32(word*64 + bits.TrailingZeros64(bitmap))
+				bitmap &= bitmap - 1
+
+				for dz := int32(0); dz < h; dz++ {
+					z0 := z - dz
+					if z0 < 0 || z0 >= z1 {
+						continue
+					}
+					for dx := int32(0); dx < w; dx++ {
+						x0 := x - dx
+						if x0 < 0 || x0 >= x1 || !maskRows[dz].bit(dx) {
+							continue
+						}
+						counts[z0][x0]++
+					}
+				}
+			}
+		}
+	}
+
+	for z0 := int32(0); z0 < z1; z0++ {
+		for x0 := int32(0); x0 < x1; x0++ {
+			if count := counts[z0][x0]; count >= int32(threshold) {
+				results = append(results, SearchResult{int(count), x0 + offX, z0 + offZ})
+			}
+		}
+	}
+	return results
+}
+
 func (sec *Section) CheckMask(x0, z0 int32, mask Mask) (count int) {
 	w, h := mask.Bounds()
+	secRows := sec.packRows()
+	maskRows := packMaskRows(mask, w, h)
+	for dz := int32(0); dz < h; dz++ {
+		count += popcountAnd(windowBits(secRows[z0+dz], x0), maskRows[dz])
+	}
+	return count
+}
+
+// rowBits packs one section or mask row into a 128-bit value split across two
+// uint64s, bit x living at rowBits[x/64]'s (x%64)'th bit.
+type rowBits [2]uint64
+
+// packRows packs every row of the section into rowBits so CheckMask and
+// Search can test a whole mask row against a whole section row in O(1).
+func (sec *Section) packRows() [SectionSize]rowBits {
+	var rows [SectionSize]rowBits
+	for z := int32(0); z < SectionSize; z++ {
+		for x := int32(0); x < SectionSize; x++ {
+			if sec.Get(x, z) {
+				rows[z].set(x)
+			}
+		}
+	}
+	return rows
+}
+
+// packMaskRows packs each row of mask (0..h-1, each w bits wide) into a
+// rowBits, so it can be ANDed directly against a windowed section row.
+func packMaskRows(mask Mask, w, h int32) []rowBits {
+	rows := make([]rowBits, h)
 	for z := int32(0); z < h; z++ {
 		for x := int32(0); x < w; x++ {
-			if sec.Get(x+x0, z+z0) && mask.Query(x, z) {
-				count++
+			if mask.Query(x, z) {
+				rows[z].set(x)
 			}
 		}
 	}
-	return count
+	return rows
+}
+
+func (rb *rowBits) set(x int32) {
+	rb[x/64] |= 1 << uint(x%64)
+}
+
+func (rb rowBits) bit(x int32) bool {
+	return rb[x/64]&(1<<uint(x%64)) != 0
+}
+
+// windowBits returns row shifted right by x0 bits, i.e. the bits of row
+// starting at column x0, ready to be ANDed against a mask row starting at 0.
+func windowBits(row rowBits, x0 int32) rowBits {
+	switch {
+	case x0 == 0:
+		return row
+	case x0 < 64:
+		return rowBits{
+			(row[0] >> uint(x0)) | (row[1] << uint(64-x0)),
+			row[1] >> uint(x0),
+		}
+	default:
+		return rowBits{row[1] >> uint(x0-64), 0}
+	}
+}
+
+func popcountAnd(a, b rowBits) int {
+	return bits.OnesCount64(a[0]&b[0]) + bits.OnesCount64(a[1]&b[1])
 }
 
 func secIdx(x, z int32) int {
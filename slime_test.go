@@ -0,0 +1,130 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+// afkMask is a small rectangular mask representative of a typical afk-pod
+// search (slime chunks within render-distance of a standing position).
+type afkMask struct {
+	w, h int32
+}
+
+func (m afkMask) Bounds() (int32, int32) { return m.w, m.h }
+func (m afkMask) Query(x, z int32) bool  { return x >= 0 && x < m.w && z >= 0 && z < m.h }
+
+// holeyMask is a non-rectangular mask (every other chunk) used to exercise
+// the bitset packing/popcount path with gaps in a row, not just solid runs.
+type holeyMask struct {
+	w, h int32
+}
+
+func (m holeyMask) Bounds() (int32, int32) { return m.w, m.h }
+func (m holeyMask) Query(x, z int32) bool {
+	return x >= 0 && x < m.w && z >= 0 && z < m.h && (x+z)%2 == 0
+}
+
+func benchSection(w World) *Section {
+	sec := &Section{X: 0, Z: 0}
+	sec.Compute(w)
+	return sec
+}
+
+// referenceSearch is a naive O(w*h) per window reference for Section.Search,
+// used to check the bitset/popcount scan (and its sparse/dense split)
+// against ground truth instead of against itself.
+func referenceSearch(sec *Section, mask Mask, threshold int) (results []SearchResult) {
+	w, h := mask.Bounds()
+	x1, z1 := SectionSize-w, SectionSize-h
+	for z := int32(0); z < z1; z++ {
+		for x := int32(0); x < x1; x++ {
+			count := 0
+			for dz := int32(0); dz < h; dz++ {
+				for dx := int32(0); dx < w; dx++ {
+					if mask.Query(dx, dz) && sec.Get(x+dx, z+dz) {
+						count++
+					}
+				}
+			}
+			if count >= threshold {
+				results = append(results, SearchResult{count, x + sec.X + w/2, z + sec.Z + h/2})
+			}
+		}
+	}
+	return results
+}
+
+// TestSearchMatchesReference checks Section.Search (and the CheckMask it
+// shares row-packing with) against referenceSearch across a handful of
+// worlds, densities, and both rectangular and non-rectangular masks, so the
+// bitset/popcount scan can't silently drift from a brute-force count.
+func TestSearchMatchesReference(t *testing.T) {
+	masks := []Mask{
+		afkMask{w: 16, h: 16},
+		afkMask{w: 4, h: 64},
+		afkMask{w: 64, h: 4},
+		holeyMask{w: 9, h: 9},
+		holeyMask{w: 3, h: 11},
+	}
+
+	sections := []*Section{benchSection(World(1)), benchSection(World(42)), benchSection(World(-7))}
+	for _, density := range []float64{0.01, 0.1, 0.4} {
+		sec := &Section{X: 5, Z: -3}
+		fillDensity(sec, density, uint64(density*1000)+7)
+		sections = append(sections, sec)
+	}
+
+	for si, sec := range sections {
+		for _, mask := range masks {
+			for _, threshold := range []int{1, 2} {
+				got := sec.Search(mask, threshold)
+				want := referenceSearch(sec, mask, threshold)
+				if !reflect.DeepEqual(got, want) {
+					t.Fatalf("section %d, mask %+v, threshold %d: Search = %v, want %v", si, mask, threshold, got, want)
+				}
+			}
+		}
+	}
+}
+
+// TestCheckMaskMatchesReference checks Section.CheckMask at a handful of
+// fixed offsets against a brute-force count.
+func TestCheckMaskMatchesReference(t *testing.T) {
+	sec := benchSection(World(42))
+	mask := holeyMask{w: 9, h: 9}
+
+	for _, off := range [][2]int32{{0, 0}, {10, 3}, {100, 100}} {
+		got := sec.CheckMask(off[0], off[1], mask)
+		want := 0
+		w, h := mask.Bounds()
+		for dz := int32(0); dz < h; dz++ {
+			for dx := int32(0); dx < w; dx++ {
+				if mask.Query(dx, dz) && sec.Get(off[0]+dx, off[1]+dz) {
+					want++
+				}
+			}
+		}
+		if got != want {
+			t.Fatalf("CheckMask(%d, %d) = %d, want %d", off[0], off[1], got, want)
+		}
+	}
+}
+
+func BenchmarkSectionSearchAfkPod(b *testing.B) {
+	sec := benchSection(World(42))
+	mask := afkMask{w: 16, h: 16}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		sec.Search(mask, 1)
+	}
+}
+
+func BenchmarkSectionCheckMask(b *testing.B) {
+	sec := benchSection(World(42))
+	mask := afkMask{w: 16, h: 16}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		sec.CheckMask(0, 0, mask)
+	}
+}
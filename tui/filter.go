@@ -0,0 +1,95 @@
+package tui
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Filter reports whether a result should be shown.
+type Filter func(Result) bool
+
+// ParseFilter parses a query typed into the browser: a bare number ("8") is
+// treated as a minimum count, and field comparisons ("count>=8", "dist<3000")
+// or ranges ("x:-500..500") are also accepted. An empty query matches
+// everything.
+func ParseFilter(query string) (Filter, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return func(Result) bool { return true }, nil
+	}
+
+	if n, err := strconv.Atoi(query); err == nil {
+		return func(r Result) bool { return r.Count >= n }, nil
+	}
+
+	if rest, ok := cutPrefix(query, "count"); ok {
+		return parseCmp(rest, func(r Result) int { return r.Count })
+	}
+	if rest, ok := cutPrefix(query, "dist"); ok {
+		return parseCmp(rest, func(r Result) int {
+			return int(math.Sqrt(float64(int64(r.X)*int64(r.X) + int64(r.Z)*int64(r.Z))))
+		})
+	}
+	if rest, ok := cutPrefix(query, "x:"); ok {
+		return parseRange(rest, func(r Result) int32 { return r.X })
+	}
+	if rest, ok := cutPrefix(query, "z:"); ok {
+		return parseRange(rest, func(r Result) int32 { return r.Z })
+	}
+
+	return nil, fmt.Errorf("tui: unrecognised query %q", query)
+}
+
+func cutPrefix(s, prefix string) (string, bool) {
+	if !strings.HasPrefix(s, prefix) {
+		return "", false
+	}
+	return s[len(prefix):], true
+}
+
+func parseCmp(expr string, field func(Result) int) (Filter, error) {
+	for _, op := range []string{">=", "<=", "==", ">", "<", "="} {
+		rest, ok := cutPrefix(expr, op)
+		if !ok {
+			continue
+		}
+		n, err := strconv.Atoi(strings.TrimSpace(rest))
+		if err != nil {
+			return nil, fmt.Errorf("tui: bad number in %q: %w", expr, err)
+		}
+		switch op {
+		case ">=":
+			return func(r Result) bool { return field(r) >= n }, nil
+		case "<=":
+			return func(r Result) bool { return field(r) <= n }, nil
+		case ">":
+			return func(r Result) bool { return field(r) > n }, nil
+		case "<":
+			return func(r Result) bool { return field(r) < n }, nil
+		default: // "==" or "="
+			return func(r Result) bool { return field(r) == n }, nil
+		}
+	}
+	return nil, fmt.Errorf("tui: missing comparison operator in %q", expr)
+}
+
+func parseRange(expr string, field func(Result) int32) (Filter, error) {
+	lo, hi, ok := strings.Cut(expr, "..")
+	if !ok {
+		return nil, fmt.Errorf("tui: expected a..b range, got %q", expr)
+	}
+	loN, err := strconv.Atoi(strings.TrimSpace(lo))
+	if err != nil {
+		return nil, fmt.Errorf("tui: bad range start %q: %w", lo, err)
+	}
+	hiN, err := strconv.Atoi(strings.TrimSpace(hi))
+	if err != nil {
+		return nil, fmt.Errorf("tui: bad range end %q: %w", hi, err)
+	}
+	return func(r Result) bool {
+		v := field(r)
+		return v >= int32(loN) && v <= int32(hiN)
+	}, nil
+}
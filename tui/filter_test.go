@@ -0,0 +1,46 @@
+package tui
+
+import "testing"
+
+func TestParseFilter(t *testing.T) {
+	results := []Result{
+		{Count: 10, X: 0, Z: 0},
+		{Count: 5, X: 3000, Z: 0},
+		{Count: 5, X: -600, Z: 0},
+	}
+
+	cases := []struct {
+		query string
+		want  []bool
+	}{
+		{"", []bool{true, true, true}},
+		{"8", []bool{true, false, false}},
+		{"count>=8", []bool{true, false, false}},
+		{"count<8", []bool{false, true, true}},
+		{"count==5", []bool{false, true, true}},
+		{"dist<3000", []bool{true, false, true}},
+		{"x:-500..500", []bool{true, false, false}},
+		{"z:0..0", []bool{true, true, true}},
+	}
+
+	for _, c := range cases {
+		f, err := ParseFilter(c.query)
+		if err != nil {
+			t.Errorf("ParseFilter(%q): %v", c.query, err)
+			continue
+		}
+		for i, r := range results {
+			if got := f(r); got != c.want[i] {
+				t.Errorf("ParseFilter(%q)(%+v) = %v, want %v", c.query, r, got, c.want[i])
+			}
+		}
+	}
+}
+
+func TestParseFilterRejectsGarbage(t *testing.T) {
+	for _, query := range []string{"count>>8", "x:500", "bogus>1"} {
+		if _, err := ParseFilter(query); err == nil {
+			t.Errorf("ParseFilter(%q): expected an error, got nil", query)
+		}
+	}
+}
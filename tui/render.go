@@ -0,0 +1,115 @@
+package tui
+
+import (
+	"fmt"
+
+	"github.com/nsf/termbox-go"
+)
+
+// render redraws the whole screen from st. It only ever lays out as many
+// rows as fit in the terminal (virtualized: a visibleResults slice with a
+// million entries still costs one screen's worth of draw calls), and keeps
+// st.scrollTop tracking st.selected so the selection is always on screen.
+func render(st *state, maxRows int) {
+	termbox.Clear(termbox.ColorDefault, termbox.ColorDefault)
+	width, height := termbox.Size()
+
+	visible := visibleResults(st.all, st.filter, maxRows)
+	st.visibleN = len(visible)
+	if st.selected >= len(visible) {
+		st.selected = len(visible) - 1
+	}
+	if st.selected < 0 {
+		st.selected = 0
+	}
+
+	header := fmt.Sprintf("%d/%d sections scanned, showing %d/%d results",
+		st.progress.Done, st.progress.Total, len(visible), len(st.all))
+	drawLine(0, 0, width, header, termbox.ColorDefault)
+
+	// rows available for the result list: total height minus the header and
+	// the prompt/status line at the bottom.
+	listRows := height - 2
+	if listRows < 0 {
+		listRows = 0
+	}
+	st.scrollTop = clampScroll(st.scrollTop, st.selected, listRows, len(visible))
+
+	for row := 0; row < listRows; row++ {
+		i := st.scrollTop + row
+		if i >= len(visible) {
+			break
+		}
+		r := visible[i]
+		line := fmt.Sprintf("count=%-4d x=%-8d z=%-8d", r.Count, r.X, r.Z)
+		fg, bg := termbox.ColorDefault, termbox.ColorDefault
+		if i == st.selected {
+			// Inverted, rather than a specific color, so the selection
+			// reads clearly regardless of the terminal's palette.
+			fg, bg = termbox.ColorBlack, termbox.ColorWhite
+		}
+		drawRow(1+row, width, line, fg, bg)
+	}
+
+	drawPrompt(st, width, height-1)
+	termbox.Flush()
+}
+
+// drawRow fills a whole row with bg first, so a selected row's highlight
+// extends across the terminal rather than stopping at the end of the text.
+func drawRow(y, width int, s string, fg, bg termbox.Attribute) {
+	for x := 0; x < width; x++ {
+		termbox.SetCell(x, y, ' ', fg, bg)
+	}
+	for i, r := range []rune(s) {
+		if i >= width {
+			break
+		}
+		termbox.SetCell(i, y, r, fg, bg)
+	}
+}
+
+func drawPrompt(st *state, width, y int) {
+	var prefix, buf string
+	switch st.mode {
+	case modeSavePrompt:
+		prefix, buf = "save to> ", st.savePath
+	default:
+		prefix, buf = "> ", st.query
+	}
+	line := prefix + buf
+	if st.status != "" {
+		line += "  (" + st.status + ")"
+	}
+	drawLine(0, y, width, line, termbox.ColorDefault)
+}
+
+func drawLine(x, y, width int, s string, fg termbox.Attribute) {
+	for i, r := range []rune(s) {
+		if x+i >= width {
+			break
+		}
+		termbox.SetCell(x+i, y, r, fg, termbox.ColorDefault)
+	}
+}
+
+// clampScroll adjusts top so that selected stays within [top, top+rows), and
+// the window never runs off either end of the result list.
+func clampScroll(top, selected, rows, total int) int {
+	if rows <= 0 {
+		return 0
+	}
+	if selected < top {
+		top = selected
+	}
+	if selected >= top+rows {
+		top = selected - rows + 1
+	}
+	if top > total-rows {
+		top = total - rows
+	}
+	if top < 0 {
+		top = 0
+	}
+	return top
+}
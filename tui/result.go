@@ -0,0 +1,50 @@
+// Package tui implements an interactive result browser for slimy searches:
+// a live-updating, filterable, scrollable view over a stream of results
+// that's still arriving, driven by a minimal termbox terminal UI so headless
+// builds (which never call Run) aren't affected by it. Retained results are
+// capped (see Options.MaxStored) so a search with far more hits than anyone
+// will look at can't grow memory without bound.
+package tui
+
+import "github.com/vktec/slimy/rank"
+
+// Result is one match found by a search, trimmed down to what the browser
+// needs to display and rank it.
+type Result struct {
+	Count int
+	X, Z  int32
+}
+
+// Progress reports how far a still-running search has gotten.
+type Progress struct {
+	Done, Total int
+}
+
+// Options configures a Run call.
+type Options struct {
+	// Results streams matches as they're found. Run reads until it's closed.
+	Results <-chan Result
+	// Progress streams search progress ticks. May be nil if unavailable.
+	Progress <-chan Progress
+	// MaxRows caps how many ranked results are kept visible at once. Zero
+	// uses a sane default.
+	MaxRows int
+	// MaxStored caps how many results are retained in total, so a search
+	// with millions of hits can't grow Run's memory without bound. Results
+	// ranked below the cap are dropped as better ones arrive. Zero uses a
+	// sane default.
+	MaxStored int
+	// Save writes the currently visible results to path, for the Ctrl+S
+	// save prompt. Nil disables the binding.
+	Save func(path string, results []Result) error
+	// Copy delivers a command (e.g. "/tp @s 123 ~ 456") to the clipboard,
+	// for Enter on the selected row. Nil disables the binding.
+	Copy func(cmd string) error
+}
+
+// resultLess orders results the same way World's default search ordering
+// does (see package rank), so ties stay in a consistent order across
+// renders.
+func resultLess(a, b Result) bool {
+	return rank.Less(rank.Point{Count: a.Count, X: a.X, Z: a.Z}, rank.Point{Count: b.Count, X: b.X, Z: b.Z})
+}
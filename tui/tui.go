@@ -0,0 +1,272 @@
+package tui
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/nsf/termbox-go"
+)
+
+const defaultMaxRows = 500
+
+// defaultMaxStored bounds how many results Run retains in total (not just
+// how many are displayed), so a search with a million hits can't grow
+// memory without bound.
+const defaultMaxStored = 20000
+
+// inputMode distinguishes what typed characters currently edit: the filter
+// query (the normal state), or a save path (while the Ctrl+S prompt is
+// open).
+type inputMode int
+
+const (
+	modeQuery inputMode = iota
+	modeSavePrompt
+)
+
+// state holds everything a render needs; kept together so the event loop in
+// Run stays a straightforward switch over what changed.
+type state struct {
+	all       []Result
+	progress  Progress
+	filter    Filter
+	query     string
+	savePath  string
+	mode      inputMode
+	selected  int // index into the current matched/visible slice
+	status    string
+	visibleN  int // how many rows the last render had, for clamping selected
+	scrollTop int // index of the first visible row shown, for scrolling
+}
+
+// Run drives the interactive result browser with a real terminal UI, in the
+// spirit of fzf: it consumes opts.Results and opts.Progress while a search
+// is still running and renders a live-updating, filterable, scrollable list.
+//
+// Typed characters edit the filter query (e.g. "count>=8", "dist<3000",
+// "x:-500..500", or a bare number treated as a minimum count), re-filtering
+// and re-ranking on every keystroke. Arrow keys move the selection; Enter
+// copies the selected row's /tp command via opts.Copy; Ctrl+S opens a prompt
+// to save the currently visible results via opts.Save (kept off the bare
+// 's' key, which has to stay typeable into the filter query); Esc closes an
+// open save prompt, and otherwise Esc/Ctrl+C quits.
+func Run(opts Options) error {
+	if err := termbox.Init(); err != nil {
+		return fmt.Errorf("tui: initialising terminal: %w", err)
+	}
+	defer termbox.Close()
+	termbox.SetInputMode(termbox.InputEsc)
+
+	maxRows := opts.MaxRows
+	if maxRows <= 0 {
+		maxRows = defaultMaxRows
+	}
+	maxStored := opts.MaxStored
+	if maxStored <= 0 {
+		maxStored = defaultMaxStored
+	}
+
+	st := &state{filter: func(Result) bool { return true }}
+
+	events := make(chan termbox.Event)
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case events <- termbox.PollEvent():
+			case <-done:
+				return
+			}
+		}
+	}()
+	defer close(done)
+
+	render(st, maxRows)
+	results, progress := opts.Results, opts.Progress
+	for {
+		select {
+		case r, ok := <-results:
+			if !ok {
+				results = nil
+				continue
+			}
+			st.all = insertBounded(st.all, r, maxStored)
+			render(st, maxRows)
+
+		case p, ok := <-progress:
+			if !ok {
+				progress = nil
+				continue
+			}
+			st.progress = p
+			render(st, maxRows)
+
+		case ev := <-events:
+			if ev.Type != termbox.EventKey {
+				continue
+			}
+			quit := handleKey(st, ev, opts, maxRows)
+			render(st, maxRows)
+			if quit {
+				return nil
+			}
+		}
+	}
+}
+
+// handleKey applies a single key event to st, returning true if Run should
+// exit.
+func handleKey(st *state, ev termbox.Event, opts Options, maxRows int) bool {
+	switch ev.Key {
+	case termbox.KeyCtrlC:
+		return true
+
+	case termbox.KeyEsc:
+		if st.mode == modeSavePrompt {
+			st.mode = modeQuery
+			st.savePath = ""
+			return false
+		}
+		return true
+
+	case termbox.KeyArrowUp:
+		if st.selected > 0 {
+			st.selected--
+		}
+		return false
+
+	case termbox.KeyArrowDown:
+		if st.selected < st.visibleN-1 {
+			st.selected++
+		}
+		return false
+
+	case termbox.KeyEnter:
+		switch st.mode {
+		case modeSavePrompt:
+			st.status = saveVisible(st, opts, maxRows)
+			st.mode = modeQuery
+			st.savePath = ""
+		default:
+			st.status = copySelected(st, opts, maxRows)
+		}
+		return false
+
+	case termbox.KeyCtrlS:
+		if opts.Save != nil && st.mode == modeQuery {
+			st.mode = modeSavePrompt
+			st.savePath = ""
+		}
+		return false
+
+	case termbox.KeyBackspace, termbox.KeyBackspace2:
+		buf := st.activeBuffer()
+		if len(*buf) > 0 {
+			*buf = (*buf)[:len(*buf)-1]
+		}
+		if st.mode == modeQuery {
+			st.applyQuery()
+		}
+		return false
+
+	case termbox.KeySpace:
+		*st.activeBuffer() += " "
+		if st.mode == modeQuery {
+			st.applyQuery()
+		}
+		return false
+	}
+
+	if ev.Ch != 0 {
+		*st.activeBuffer() += string(ev.Ch)
+		if st.mode == modeQuery {
+			st.applyQuery()
+		}
+	}
+	return false
+}
+
+// activeBuffer returns whichever text buffer typed characters currently
+// edit, so key handling doesn't need to branch on mode for every key.
+func (st *state) activeBuffer() *string {
+	if st.mode == modeSavePrompt {
+		return &st.savePath
+	}
+	return &st.query
+}
+
+// applyQuery re-parses st.query into st.filter and moves the selection back
+// to the top row. A bad query is reported in
+// st.status and leaves the previous filter in place, rather than discarding
+// whatever the user had typed.
+func (st *state) applyQuery() {
+	f, err := ParseFilter(st.query)
+	if err != nil {
+		st.status = err.Error()
+		return
+	}
+	st.filter = f
+	st.status = ""
+	st.selected = 0
+}
+
+func copySelected(st *state, opts Options, maxRows int) string {
+	if opts.Copy == nil {
+		return "tui: copying results isn't supported"
+	}
+	visible := visibleResults(st.all, st.filter, maxRows)
+	if st.selected >= len(visible) {
+		return "tui: no result selected"
+	}
+	r := visible[st.selected]
+	if err := opts.Copy(fmt.Sprintf("/tp @s %d ~ %d", r.X*16, r.Z*16)); err != nil {
+		return err.Error()
+	}
+	return fmt.Sprintf("copied /tp for (%d, %d)", r.X, r.Z)
+}
+
+func saveVisible(st *state, opts Options, maxRows int) string {
+	if st.savePath == "" {
+		return "tui: no path given"
+	}
+	visible := visibleResults(st.all, st.filter, maxRows)
+	if err := opts.Save(st.savePath, visible); err != nil {
+		return err.Error()
+	}
+	return fmt.Sprintf("saved %d result(s) to %s", len(visible), st.savePath)
+}
+
+// insertBounded inserts r into all, which is kept sorted by resultLess, and
+// drops the lowest-ranked entry once len(all) exceeds maxStored. This is how
+// Run keeps total memory bounded even when a search turns up far more
+// results than anyone will ever look at.
+func insertBounded(all []Result, r Result, maxStored int) []Result {
+	i := sort.Search(len(all), func(i int) bool { return resultLess(r, all[i]) })
+	if i == len(all) {
+		if len(all) >= maxStored {
+			return all
+		}
+		return append(all, r)
+	}
+	all = append(all, Result{})
+	copy(all[i+1:], all[i:])
+	all[i] = r
+	if len(all) > maxStored {
+		all = all[:maxStored]
+	}
+	return all
+}
+
+func visibleResults(all []Result, filter Filter, maxRows int) []Result {
+	var matched []Result
+	for _, r := range all {
+		if filter(r) {
+			matched = append(matched, r)
+		}
+	}
+	sort.SliceStable(matched, func(i, j int) bool { return resultLess(matched[i], matched[j]) })
+	if len(matched) > maxRows {
+		matched = matched[:maxRows]
+	}
+	return matched
+}
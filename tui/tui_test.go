@@ -0,0 +1,53 @@
+package tui
+
+import "testing"
+
+func TestInsertBoundedKeepsTopNByRank(t *testing.T) {
+	var all []Result
+	for _, count := range []int{3, 1, 4, 1, 5, 9, 2, 6} {
+		all = insertBounded(all, Result{Count: count}, 3)
+	}
+	if len(all) != 3 {
+		t.Fatalf("len(all) = %d, want 3", len(all))
+	}
+	for i := 1; i < len(all); i++ {
+		if !resultLess(all[i-1], all[i]) && all[i-1] != all[i] {
+			t.Fatalf("all[%d]=%+v is not ranked before all[%d]=%+v", i-1, all[i-1], i, all[i])
+		}
+	}
+	counts := []int{all[0].Count, all[1].Count, all[2].Count}
+	want := []int{9, 6, 5}
+	for i := range want {
+		if counts[i] != want[i] {
+			t.Fatalf("counts = %v, want %v", counts, want)
+		}
+	}
+}
+
+func TestVisibleResultsFiltersAndCaps(t *testing.T) {
+	all := []Result{{Count: 1}, {Count: 5}, {Count: 3}, {Count: 8}}
+	filter := func(r Result) bool { return r.Count >= 3 }
+
+	got := visibleResults(all, filter, 2)
+	if len(got) != 2 {
+		t.Fatalf("len(got) = %d, want 2", len(got))
+	}
+	if got[0].Count != 8 || got[1].Count != 5 {
+		t.Fatalf("got = %+v, want counts [8 5]", got)
+	}
+}
+
+func TestClampScrollKeepsSelectionOnScreen(t *testing.T) {
+	// Selection below the window scrolls down just enough to show it.
+	if top := clampScroll(0, 10, 5, 20); top != 6 {
+		t.Errorf("clampScroll(0, 10, 5, 20) = %d, want 6", top)
+	}
+	// Selection above the window scrolls up to it.
+	if top := clampScroll(10, 2, 5, 20); top != 2 {
+		t.Errorf("clampScroll(10, 2, 5, 20) = %d, want 2", top)
+	}
+	// Never scrolls past the end of the list.
+	if top := clampScroll(0, 19, 5, 20); top != 15 {
+		t.Errorf("clampScroll(0, 19, 5, 20) = %d, want 15", top)
+	}
+}